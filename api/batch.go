@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchRequest is one virtual sub-request dispatched by BatchClient.Do,
+// mirroring the shape of a single generated operation call.
+type BatchRequest struct {
+	// Name labels this item in BatchResult.Items for the caller's own
+	// bookkeeping; it is not sent to the server.
+	Name string
+	// Call performs the actual typed operation against client, returning
+	// the same Res interface the method would return on its own, e.g.
+	// client.Users().GetUserByUuid(ctx, uuid).
+	Call func(ctx context.Context, client *ClientExt) (any, error)
+	// Timeout, if set, bounds this item independently of the others.
+	Timeout time.Duration
+}
+
+// BatchItemResult is one entry of a BatchResult, in the same order as the
+// BatchRequest it was dispatched from.
+type BatchItemResult struct {
+	Name     string
+	Response any
+	Err      error
+}
+
+// BatchResult is the ordered outcome of a BatchClient.Do call.
+type BatchResult struct {
+	Items []BatchItemResult
+}
+
+// BatchMode selects how BatchClient.Do dispatches its items.
+type BatchMode int
+
+const (
+	// BatchModeParallel fires every item concurrently and joins on the
+	// slowest one.
+	BatchModeParallel BatchMode = iota
+	// BatchModeSequential dispatches items one at a time and stops at the
+	// first error.
+	BatchModeSequential
+)
+
+// BatchOpts configures BatchClient.Do.
+type BatchOpts struct {
+	Mode BatchMode
+	// Parallelism caps the number of items in flight at once in
+	// BatchModeParallel. Defaults to the number of items when zero.
+	Parallelism int
+}
+
+// BatchClient coalesces several heterogeneous sub-requests into a single
+// logical call, so dashboards that need e.g. a user, a node, and the host
+// list don't pay for six independent round trips one at a time.
+type BatchClient struct {
+	client *ClientExt
+}
+
+// NewBatchClient creates a new BatchClient.
+func NewBatchClient(client *ClientExt) *BatchClient {
+	return &BatchClient{client: client}
+}
+
+// Batch returns a BatchClient bound to ce. Unlike the other sub-clients,
+// it is constructed fresh on each call rather than cached on ClientExt
+// since it carries no state of its own.
+func (ce *ClientExt) Batch() *BatchClient {
+	return NewBatchClient(ce)
+}
+
+// Do dispatches every item in requests against the underlying ClientExt and
+// returns their responses in the same order, either in parallel (joining on
+// the slowest item) or sequentially (stopping at the first error) per
+// opts.Mode.
+func (bc *BatchClient) Do(ctx context.Context, requests []BatchRequest, opts BatchOpts) (*BatchResult, error) {
+	if opts.Mode == BatchModeSequential {
+		return bc.doSequential(ctx, requests)
+	}
+	return bc.doParallel(ctx, requests, opts)
+}
+
+func (bc *BatchClient) doSequential(ctx context.Context, requests []BatchRequest) (*BatchResult, error) {
+	result := &BatchResult{Items: make([]BatchItemResult, len(requests))}
+	for i, req := range requests {
+		itemCtx, cancel := withOperationDeadline(ctx, req.Timeout)
+		resp, err := req.Call(itemCtx, bc.client)
+		cancel()
+
+		result.Items[i] = BatchItemResult{Name: req.Name, Response: resp, Err: err}
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (bc *BatchClient) doParallel(ctx context.Context, requests []BatchRequest, opts BatchOpts) (*BatchResult, error) {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = len(requests)
+	}
+	if parallelism <= 0 {
+		return &BatchResult{}, nil
+	}
+
+	result := &BatchResult{Items: make([]BatchItemResult, len(requests))}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, req := range requests {
+		i, req := i, req
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			itemCtx, cancel := withOperationDeadline(ctx, req.Timeout)
+			defer cancel()
+
+			resp, err := req.Call(itemCtx, bc.client)
+			result.Items[i] = BatchItemResult{Name: req.Name, Response: resp, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return result, nil
+}
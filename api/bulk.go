@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// bulkRetryBaseDelay and bulkRetryMaxDelay bound the exponential backoff
+// applied between a chunk's retry attempts in executeChunkWithRetry.
+const (
+	bulkRetryBaseDelay = 200 * time.Millisecond
+	bulkRetryMaxDelay  = 5 * time.Second
+)
+
+// bulkRetryDelay computes the exponential-backoff delay before the given
+// retry attempt (0-indexed), capped at bulkRetryMaxDelay. It shares its
+// formula with the transport-level RetryPolicy via expBackoff (resilience.go)
+// rather than reimplementing it.
+func bulkRetryDelay(attempt int) time.Duration {
+	return expBackoff(bulkRetryBaseDelay, bulkRetryMaxDelay, attempt)
+}
+
+// isTransientBulkError reports whether err is worth retrying: network and
+// server-side failures (including an *InternalServerError response) are, but
+// a chunk that the server rejected as malformed (surfaced as an
+// UnexpectedResponseError by dispatchBulkChunk) will fail the same way on
+// every attempt, so retrying it just burns time.
+func isTransientBulkError(err error) bool {
+	var unexpected *UnexpectedResponseError
+	if !errors.As(err, &unexpected) {
+		return true
+	}
+	_, serverErr := unexpected.Response.(*InternalServerError)
+	return serverErr
+}
+
+// BulkOp identifies which bulk endpoint Execute dispatches chunks to.
+type BulkOp int
+
+const (
+	BulkOpDeleteUsers BulkOp = iota
+	BulkOpEnableUsers
+	BulkOpDisableUsers
+	BulkOpEnableHosts
+	BulkOpDisableHosts
+	BulkOpDeleteHosts
+)
+
+// BulkOpts configures Execute.
+type BulkOpts struct {
+	// ChunkSize is the number of UUIDs sent per request. Defaults to 100.
+	ChunkSize int
+	// Concurrency is the number of chunks in flight at once. Defaults to 4.
+	Concurrency int
+	// MaxRetries is how many times a chunk is retried after a transient
+	// (network or 5xx) failure before its UUIDs are recorded as failed.
+	MaxRetries int
+	// DryRun, when true, skips the network calls entirely and reports every
+	// UUID as succeeded, so callers can preview chunking/ordering.
+	DryRun bool
+	// Progress, if set, is called after each chunk completes with the
+	// number of UUIDs processed so far and the total.
+	Progress func(done, total int)
+}
+
+func (o BulkOpts) chunkSize() int {
+	if o.ChunkSize > 0 {
+		return o.ChunkSize
+	}
+	return 100
+}
+
+func (o BulkOpts) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return 4
+}
+
+// BulkResult aggregates the per-UUID outcome of an Execute call.
+type BulkResult struct {
+	Succeeded []string
+	Failed    map[string]error
+}
+
+func newBulkResult() *BulkResult {
+	return &BulkResult{Failed: make(map[string]error)}
+}
+
+func (r *BulkResult) mergeSuccess(uuids []string) {
+	r.Succeeded = append(r.Succeeded, uuids...)
+}
+
+func (r *BulkResult) mergeFailure(uuids []string, err error) {
+	for _, u := range uuids {
+		r.Failed[u] = err
+	}
+}
+
+// dispatchBulkChunk sends one chunk to the operation's underlying endpoint,
+// surfacing a response the server didn't reject at the transport level but
+// that doesn't decode to the expected success DTO (e.g. a validation error
+// shape) as an *UnexpectedResponseError, the same way the IterateAll helpers
+// in iterator.go do, so executeChunkWithRetry can tell it apart from a
+// transient failure.
+func dispatchBulkChunk(ctx context.Context, client *Client, op BulkOp, uuids []string) error {
+	req := &BulkUuidsRequest{Uuids: uuids}
+
+	switch op {
+	case BulkOpDeleteUsers:
+		resp, err := client.UsersControllerBulkDeleteUsers(withOperationName(ctx, "UsersControllerBulkDeleteUsers"), req)
+		return checkBulkResponse(err, resp, "UsersControllerBulkDeleteUsers", func(r UsersControllerBulkDeleteUsersRes) bool {
+			_, ok := r.(*BulkDeleteUsersResponse)
+			return ok
+		})
+	case BulkOpEnableUsers:
+		resp, err := client.UsersControllerBulkEnableUsers(withOperationName(ctx, "UsersControllerBulkEnableUsers"), req)
+		return checkBulkResponse(err, resp, "UsersControllerBulkEnableUsers", func(r UsersControllerBulkEnableUsersRes) bool {
+			_, ok := r.(*BulkEnableUsersResponse)
+			return ok
+		})
+	case BulkOpDisableUsers:
+		resp, err := client.UsersControllerBulkDisableUsers(withOperationName(ctx, "UsersControllerBulkDisableUsers"), req)
+		return checkBulkResponse(err, resp, "UsersControllerBulkDisableUsers", func(r UsersControllerBulkDisableUsersRes) bool {
+			_, ok := r.(*BulkDisableUsersResponse)
+			return ok
+		})
+	case BulkOpEnableHosts:
+		resp, err := client.HostsControllerBulkEnableHosts(withOperationName(ctx, "HostsControllerBulkEnableHosts"), req)
+		return checkBulkResponse(err, resp, "HostsControllerBulkEnableHosts", func(r HostsControllerBulkEnableHostsRes) bool {
+			_, ok := r.(*BulkEnableHostsResponse)
+			return ok
+		})
+	case BulkOpDisableHosts:
+		resp, err := client.HostsControllerBulkDisableHosts(withOperationName(ctx, "HostsControllerBulkDisableHosts"), req)
+		return checkBulkResponse(err, resp, "HostsControllerBulkDisableHosts", func(r HostsControllerBulkDisableHostsRes) bool {
+			_, ok := r.(*BulkDisableHostsResponse)
+			return ok
+		})
+	case BulkOpDeleteHosts:
+		resp, err := client.HostsControllerBulkDeleteHosts(withOperationName(ctx, "HostsControllerBulkDeleteHosts"), req)
+		return checkBulkResponse(err, resp, "HostsControllerBulkDeleteHosts", func(r HostsControllerBulkDeleteHostsRes) bool {
+			_, ok := r.(*BulkDeleteHostsResponse)
+			return ok
+		})
+	}
+	return nil
+}
+
+// checkBulkResponse turns a non-transport-level rejection (resp decoded
+// fine but isn't the expected success DTO) into an *UnexpectedResponseError,
+// leaving a genuine transport error (network, decode failure) untouched.
+func checkBulkResponse[T any](err error, resp T, operation string, isSuccess func(T) bool) error {
+	if err != nil {
+		return err
+	}
+	if !isSuccess(resp) {
+		return newUnexpectedResponseError(operation, resp)
+	}
+	return nil
+}
+
+// Execute splits uuids into chunks, runs opts.Concurrency workers in
+// parallel against the underlying bulk endpoint for op, retries a chunk up
+// to opts.MaxRetries times on a transient failure, and aggregates per-UUID
+// results. In DryRun mode no requests are made and every UUID is reported as
+// succeeded.
+func (sc *UsersBulkActionsClient) Execute(ctx context.Context, op BulkOp, uuids []string, opts BulkOpts) (*BulkResult, error) {
+	return executeBulk(ctx, sc.client, op, uuids, opts)
+}
+
+// Execute splits uuids into chunks, runs opts.Concurrency workers in
+// parallel against the underlying bulk endpoint for op, retries a chunk up
+// to opts.MaxRetries times on a transient failure, and aggregates per-UUID
+// results. In DryRun mode no requests are made and every UUID is reported as
+// succeeded.
+func (sc *HostsBulkActionsClient) Execute(ctx context.Context, op BulkOp, uuids []string, opts BulkOpts) (*BulkResult, error) {
+	return executeBulk(ctx, sc.client, op, uuids, opts)
+}
+
+func executeBulk(ctx context.Context, client *Client, op BulkOp, uuids []string, opts BulkOpts) (*BulkResult, error) {
+	chunks := chunkUUIDs(uuids, opts.chunkSize())
+
+	result := newBulkResult()
+	var mu sync.Mutex
+	var done int
+	total := len(uuids)
+
+	sem := make(chan struct{}, opts.concurrency())
+	var wg sync.WaitGroup
+
+	for _, chunk := range chunks {
+		chunk := chunk
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := executeChunkWithRetry(ctx, client, op, chunk, opts)
+
+			mu.Lock()
+			if err != nil {
+				result.mergeFailure(chunk, err)
+			} else {
+				result.mergeSuccess(chunk)
+			}
+			done += len(chunk)
+			if opts.Progress != nil {
+				opts.Progress(done, total)
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return result, ctx.Err()
+}
+
+func executeChunkWithRetry(ctx context.Context, client *Client, op BulkOp, chunk []string, opts BulkOpts) error {
+	if opts.DryRun {
+		return nil
+	}
+
+	var err error
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if err = dispatchBulkChunk(ctx, client, op, chunk); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !isTransientBulkError(err) {
+			return err
+		}
+		if attempt < opts.MaxRetries {
+			if werr := sleepContext(ctx, bulkRetryDelay(attempt)); werr != nil {
+				return werr
+			}
+		}
+	}
+	return err
+}
+
+func chunkUUIDs(uuids []string, size int) [][]string {
+	if len(uuids) == 0 {
+		return nil
+	}
+	chunks := make([][]string, 0, (len(uuids)+size-1)/size)
+	for i := 0; i < len(uuids); i += size {
+		end := i + size
+		if end > len(uuids) {
+			end = len(uuids)
+		}
+		chunks = append(chunks, uuids[i:end])
+	}
+	return chunks
+}
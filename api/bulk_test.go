@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBulkRetryDelayGrowsAndCaps(t *testing.T) {
+	prev := time.Duration(0)
+	for attempt := 0; attempt < 10; attempt++ {
+		d := bulkRetryDelay(attempt)
+		if d < prev {
+			t.Errorf("attempt %d delay %s is less than previous %s", attempt, d, prev)
+		}
+		if d > bulkRetryMaxDelay {
+			t.Errorf("attempt %d delay %s exceeds cap %s", attempt, d, bulkRetryMaxDelay)
+		}
+		prev = d
+	}
+}
+
+func TestIsTransientBulkError(t *testing.T) {
+	if !isTransientBulkError(errors.New("connection reset")) {
+		t.Error("a generic network-shaped error should be treated as transient")
+	}
+	if !isTransientBulkError(context.DeadlineExceeded) {
+		t.Error("a deadline error should be treated as transient")
+	}
+	if isTransientBulkError(&UnexpectedResponseError{Operation: "UsersControllerBulkDeleteUsers"}) {
+		t.Error("an UnexpectedResponseError should not be treated as transient")
+	}
+}
+
+func TestCheckBulkResponse(t *testing.T) {
+	if err := checkBulkResponse[string](nil, "success", "op", func(s string) bool { return s == "success" }); err != nil {
+		t.Errorf("expected no error for a recognized success response, got %v", err)
+	}
+
+	err := checkBulkResponse[string](nil, "validation-error", "op", func(s string) bool { return s == "success" })
+	var unexpected *UnexpectedResponseError
+	if !errors.As(err, &unexpected) {
+		t.Fatalf("expected *UnexpectedResponseError for an unrecognized response, got %v (%T)", err, err)
+	}
+	if isTransientBulkError(err) {
+		t.Error("checkBulkResponse's UnexpectedResponseError should be classified as non-transient")
+	}
+
+	transport := errors.New("connection reset")
+	if got := checkBulkResponse[string](transport, "", "op", func(s string) bool { return true }); got != transport {
+		t.Errorf("a transport error should pass through unchanged, got %v", got)
+	}
+}
@@ -203,12 +203,12 @@ func NewApiTokensClient(client *Client) *ApiTokensClient {
 
 // Create calls ApiTokensController_create.
 func (sc *ApiTokensClient) Create(ctx context.Context, request *CreateApiTokenRequestDto) (ApiTokensControllerCreateRes, error) {
-	return sc.client.ApiTokensControllerCreate(ctx, request)
+	return sc.client.ApiTokensControllerCreate(withOperationName(ctx, "ApiTokensControllerCreate"), request)
 }
 
 // Delete calls ApiTokensController_delete.
 func (sc *ApiTokensClient) Delete(ctx context.Context, params ApiTokensControllerDeleteParams) (ApiTokensControllerDeleteRes, error) {
-	return sc.client.ApiTokensControllerDelete(ctx, params)
+	return sc.client.ApiTokensControllerDelete(withOperationName(ctx, "ApiTokensControllerDelete"), params)
 }
 
 // AuthClient provides Auth operations.
@@ -223,12 +223,12 @@ func NewAuthClient(client *Client) *AuthClient {
 
 // Login calls AuthController_login.
 func (sc *AuthClient) Login(ctx context.Context, request *LoginRequestDto) (AuthControllerLoginRes, error) {
-	return sc.client.AuthControllerLogin(ctx, request)
+	return sc.client.AuthControllerLogin(withOperationName(ctx, "AuthControllerLogin"), request)
 }
 
 // Register calls AuthController_register.
 func (sc *AuthClient) Register(ctx context.Context, request *RegisterRequestDto) (AuthControllerRegisterRes, error) {
-	return sc.client.AuthControllerRegister(ctx, request)
+	return sc.client.AuthControllerRegister(withOperationName(ctx, "AuthControllerRegister"), request)
 }
 
 // ConfigProfileClient provides ConfigProfile operations.
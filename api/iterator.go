@@ -0,0 +1,412 @@
+package api
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// IterateOptions configures the paging iterators below.
+type IterateOptions struct {
+	// BatchSize is the page size requested from the server (the `size`
+	// query parameter). Defaults to 50 when zero.
+	BatchSize int
+	// PrefetchPages bounds how many pages are fetched ahead of the consumer
+	// in a background goroutine. Only used when Concurrency <= 1. A value of
+	// 1 (the default) fetches the next page while the current one is being
+	// processed; higher values trade memory for more overlap with slow
+	// consumers.
+	PrefetchPages int
+	// Concurrency bounds how many pages are fetched from the server at
+	// once. A value of 1 (the default) preserves the original
+	// single-background-goroutine prefetch behavior; values above 1 fetch
+	// that many pages in parallel once the first page has reported the
+	// total item count, reassembling them in page order before yielding so
+	// consumers still see items in the server's original ordering.
+	Concurrency int
+}
+
+func (o IterateOptions) batchSize() int {
+	if o.BatchSize > 0 {
+		return o.BatchSize
+	}
+	return 50
+}
+
+func (o IterateOptions) prefetchPages() int {
+	if o.PrefetchPages > 0 {
+		return o.PrefetchPages
+	}
+	return 1
+}
+
+func (o IterateOptions) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return 1
+}
+
+// fetchPageFunc fetches a single page starting at `start` with the given
+// `size`, returning the items on the page and the total item count reported
+// by the server.
+type fetchPageFunc[T any] func(ctx context.Context, start, size int) (items []T, total int, err error)
+
+// iteratePages drives fetchPageFunc across all pages and yields one (item,
+// error) pair at a time, honoring ctx cancellation and prefetching up to
+// opts.PrefetchPages pages ahead of the consumer.
+func iteratePages[T any](ctx context.Context, opts IterateOptions, fetch fetchPageFunc[T]) iter.Seq2[T, error] {
+	if opts.concurrency() > 1 {
+		return iteratePagesConcurrent(ctx, opts, fetch)
+	}
+
+	size := opts.batchSize()
+	prefetch := opts.prefetchPages()
+
+	return func(yield func(T, error) bool) {
+		type pageResult struct {
+			items []T
+			total int
+			err   error
+		}
+
+		results := make(chan pageResult, prefetch)
+		done := make(chan struct{})
+		go func() {
+			defer close(results)
+			start := 0
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				default:
+				}
+
+				items, total, err := fetch(ctx, start, size)
+				select {
+				case results <- pageResult{items: items, total: total, err: err}:
+				case <-done:
+					return
+				}
+				if err != nil || start+len(items) >= total || len(items) == 0 {
+					return
+				}
+				start += len(items)
+			}
+		}()
+		defer close(done)
+
+		var zero T
+		for res := range results {
+			if res.err != nil {
+				yield(zero, res.err)
+				return
+			}
+			for _, item := range res.items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// iteratePagesConcurrent is the opts.Concurrency > 1 counterpart to
+// iteratePages: it fetches the first page synchronously to learn the total
+// item count, then dispatches the remaining pages to opts.Concurrency
+// worker goroutines, reassembling their results in page order before
+// yielding so the consumer still observes the server's original item
+// ordering despite pages completing out of order.
+func iteratePagesConcurrent[T any](ctx context.Context, opts IterateOptions, fetch fetchPageFunc[T]) iter.Seq2[T, error] {
+	size := opts.batchSize()
+	workers := opts.concurrency()
+
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		firstItems, total, err := fetch(ctx, 0, size)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		for _, item := range firstItems {
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if len(firstItems) == 0 || len(firstItems) >= total {
+			return
+		}
+
+		var starts []int
+		for start := len(firstItems); start < total; start += size {
+			starts = append(starts, start)
+		}
+		if len(starts) == 0 {
+			return
+		}
+
+		type pageResult struct {
+			items []T
+			err   error
+		}
+
+		resultsByPage := make([]chan pageResult, len(starts))
+		for i := range resultsByPage {
+			resultsByPage[i] = make(chan pageResult, 1)
+		}
+
+		jobs := make(chan int)
+		done := make(chan struct{})
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for idx := range jobs {
+					items, _, ferr := fetch(ctx, starts[idx], size)
+					select {
+					case resultsByPage[idx] <- pageResult{items: items, err: ferr}:
+					case <-done:
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			defer close(jobs)
+			for i := range starts {
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		// close(done) must run before wg.Wait(): defers run LIFO, and
+		// signaling the dispatcher/workers to stop before blocking on their
+		// exit is what bounds the in-flight fetches on an early return.
+		defer wg.Wait()
+		defer close(done)
+
+		for _, results := range resultsByPage {
+			select {
+			case res := <-results:
+				if res.err != nil {
+					yield(zero, res.err)
+					return
+				}
+				for _, item := range res.items {
+					if !yield(item, nil) {
+						return
+					}
+				}
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			}
+		}
+	}
+}
+
+// Collect materializes a Seq2 iterator into a slice, stopping at the first
+// error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var out []T
+	for item, err := range seq {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// CollectAll materializes up to max items from seq, stopping early without
+// error once the cap is reached. Use it instead of Collect when iterating
+// an endpoint whose total size isn't bounded by the caller, to avoid
+// unbounded memory growth.
+func CollectAll[T any](seq iter.Seq2[T, error], max int) ([]T, error) {
+	var out []T
+	for item, err := range seq {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, item)
+		if max > 0 && len(out) >= max {
+			break
+		}
+	}
+	return out, nil
+}
+
+// IterateAll streams every user returned by GetAllUsers, transparently
+// paging via the `start`/`size` query parameters.
+func (sc *UsersClient) IterateAll(ctx context.Context, opts IterateOptions) iter.Seq2[*UserItem, error] {
+	return iteratePages(ctx, opts, func(ctx context.Context, start, size int) ([]*UserItem, int, error) {
+		resp, err := sc.client.UsersControllerGetAllUsers(withOperationName(ctx, "UsersControllerGetAllUsers"), UsersControllerGetAllUsersParams{Start: start, Size: size})
+		if err != nil {
+			return nil, 0, err
+		}
+		users, ok := resp.(*GetAllUsersResponse)
+		if !ok {
+			return nil, 0, newUnexpectedResponseError("UsersControllerGetAllUsers", resp)
+		}
+		items := make([]*UserItem, len(users.Response.Users))
+		for i := range users.Response.Users {
+			items[i] = &users.Response.Users[i]
+		}
+		return items, int(users.Response.Total), nil
+	})
+}
+
+// IterateAll streams every hwid device entry returned by GetAllUsers,
+// transparently paging via the `start`/`size` query parameters.
+func (sc *HwidUserDevicesClient) IterateAll(ctx context.Context, opts IterateOptions) iter.Seq2[*HwidDeviceItem, error] {
+	return iteratePages(ctx, opts, func(ctx context.Context, start, size int) ([]*HwidDeviceItem, int, error) {
+		resp, err := sc.client.HwidUserDevicesControllerGetAllUsers(withOperationName(ctx, "HwidUserDevicesControllerGetAllUsers"), HwidUserDevicesControllerGetAllUsersParams{Start: start, Size: size})
+		if err != nil {
+			return nil, 0, err
+		}
+		devices, ok := resp.(*GetAllHwidDevicesResponse)
+		if !ok {
+			return nil, 0, newUnexpectedResponseError("HwidUserDevicesControllerGetAllUsers", resp)
+		}
+		items := make([]*HwidDeviceItem, len(devices.Response.Devices))
+		for i := range devices.Response.Devices {
+			items[i] = &devices.Response.Devices[i]
+		}
+		return items, int(devices.Response.Total), nil
+	})
+}
+
+// IterateAll streams every node usage history record for the given node,
+// transparently paging via the `start`/`size` query parameters.
+func (sc *NodesUsageHistoryClient) IterateAll(ctx context.Context, params NodesUsageHistoryControllerGetHistoryParams, opts IterateOptions) iter.Seq2[*NodeUsageHistoryItem, error] {
+	return iteratePages(ctx, opts, func(ctx context.Context, start, size int) ([]*NodeUsageHistoryItem, int, error) {
+		p := params
+		p.Start = start
+		p.Size = size
+		resp, err := sc.client.NodesUsageHistoryControllerGetHistory(withOperationName(ctx, "NodesUsageHistoryControllerGetHistory"), p)
+		if err != nil {
+			return nil, 0, err
+		}
+		history, ok := resp.(*NodeUsageHistoryResponse)
+		if !ok {
+			return nil, 0, newUnexpectedResponseError("NodesUsageHistoryControllerGetHistory", resp)
+		}
+		items := make([]*NodeUsageHistoryItem, len(history.Response.Records))
+		for i := range history.Response.Records {
+			items[i] = &history.Response.Records[i]
+		}
+		return items, int(history.Response.Total), nil
+	})
+}
+
+// IterateAll streams every subscription request history record for the
+// given user, transparently paging via the `start`/`size` query parameters.
+func (sc *UserSubscriptionRequestHistoryClient) IterateAll(ctx context.Context, params UserSubscriptionRequestHistoryControllerGetHistoryParams, opts IterateOptions) iter.Seq2[*SubscriptionRequestHistoryItem, error] {
+	return iteratePages(ctx, opts, func(ctx context.Context, start, size int) ([]*SubscriptionRequestHistoryItem, int, error) {
+		p := params
+		p.Start = start
+		p.Size = size
+		resp, err := sc.client.UserSubscriptionRequestHistoryControllerGetHistory(withOperationName(ctx, "UserSubscriptionRequestHistoryControllerGetHistory"), p)
+		if err != nil {
+			return nil, 0, err
+		}
+		history, ok := resp.(*SubscriptionRequestHistoryResponse)
+		if !ok {
+			return nil, 0, newUnexpectedResponseError("UserSubscriptionRequestHistoryControllerGetHistory", resp)
+		}
+		items := make([]*SubscriptionRequestHistoryItem, len(history.Response.Records))
+		for i := range history.Response.Records {
+			items[i] = &history.Response.Records[i]
+		}
+		return items, int(history.Response.Total), nil
+	})
+}
+
+// IterateAll streams every node returned by GetAllNodes, transparently
+// paging via the `start`/`size` query parameters.
+func (sc *NodesClient) IterateAll(ctx context.Context, opts IterateOptions) iter.Seq2[*NodeItem, error] {
+	return iteratePages(ctx, opts, func(ctx context.Context, start, size int) ([]*NodeItem, int, error) {
+		resp, err := sc.client.NodesControllerGetAllNodes(withOperationName(ctx, "NodesControllerGetAllNodes"), NodesControllerGetAllNodesParams{Start: start, Size: size})
+		if err != nil {
+			return nil, 0, err
+		}
+		nodes, ok := resp.(*GetAllNodesResponse)
+		if !ok {
+			return nil, 0, newUnexpectedResponseError("NodesControllerGetAllNodes", resp)
+		}
+		items := make([]*NodeItem, len(nodes.Response.Nodes))
+		for i := range nodes.Response.Nodes {
+			items[i] = &nodes.Response.Nodes[i]
+		}
+		return items, int(nodes.Response.Total), nil
+	})
+}
+
+// IterateAll streams every host returned by GetAllHosts, transparently
+// paging via the `start`/`size` query parameters.
+func (sc *HostsClient) IterateAll(ctx context.Context, opts IterateOptions) iter.Seq2[*HostItem, error] {
+	return iteratePages(ctx, opts, func(ctx context.Context, start, size int) ([]*HostItem, int, error) {
+		resp, err := sc.client.HostsControllerGetAllHosts(withOperationName(ctx, "HostsControllerGetAllHosts"), HostsControllerGetAllHostsParams{Start: start, Size: size})
+		if err != nil {
+			return nil, 0, err
+		}
+		hosts, ok := resp.(*GetAllHostsResponse)
+		if !ok {
+			return nil, 0, newUnexpectedResponseError("HostsControllerGetAllHosts", resp)
+		}
+		items := make([]*HostItem, len(hosts.Response.Hosts))
+		for i := range hosts.Response.Hosts {
+			items[i] = &hosts.Response.Hosts[i]
+		}
+		return items, int(hosts.Response.Total), nil
+	})
+}
+
+// IterateAll streams every subscription returned by GetAllSubscriptions,
+// transparently paging via the `start`/`size` query parameters.
+func (sc *SubscriptionsClient) IterateAll(ctx context.Context, opts IterateOptions) iter.Seq2[*SubscriptionItem, error] {
+	return iteratePages(ctx, opts, func(ctx context.Context, start, size int) ([]*SubscriptionItem, int, error) {
+		resp, err := sc.client.SubscriptionsControllerGetAllSubscriptions(withOperationName(ctx, "SubscriptionsControllerGetAllSubscriptions"), SubscriptionsControllerGetAllSubscriptionsParams{Start: start, Size: size})
+		if err != nil {
+			return nil, 0, err
+		}
+		subs, ok := resp.(*GetAllSubscriptionsResponse)
+		if !ok {
+			return nil, 0, newUnexpectedResponseError("SubscriptionsControllerGetAllSubscriptions", resp)
+		}
+		items := make([]*SubscriptionItem, len(subs.Response.Subscriptions))
+		for i := range subs.Response.Subscriptions {
+			items[i] = &subs.Response.Subscriptions[i]
+		}
+		return items, int(subs.Response.Total), nil
+	})
+}
+
+// newUnexpectedResponseError reports that a typed sub-client call returned a
+// Res variant the iterator helpers don't know how to page over (e.g. an
+// error DTO), distinct from a transport-level error.
+func newUnexpectedResponseError(operation string, resp any) error {
+	return &UnexpectedResponseError{Operation: operation, Response: resp}
+}
+
+// UnexpectedResponseError is returned when an iterator encounters a Res
+// variant it cannot page over, such as an error response.
+type UnexpectedResponseError struct {
+	Operation string
+	Response  any
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	return "api: unexpected response type for " + e.Operation
+}
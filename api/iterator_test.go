@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestIteratePagesConcurrentPreservesOrder verifies that pages fetched in
+// parallel are yielded back in their original page order even when later
+// pages finish fetching before earlier ones.
+func TestIteratePagesConcurrentPreservesOrder(t *testing.T) {
+	const (
+		totalItems = 23
+		pageSize   = 5
+	)
+
+	var inFlight, maxInFlight int32
+	fetch := func(ctx context.Context, start, size int) ([]int, int, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			cur := atomic.LoadInt32(&maxInFlight)
+			if n <= cur || atomic.CompareAndSwapInt32(&maxInFlight, cur, n) {
+				break
+			}
+		}
+
+		// Make later pages resolve faster than earlier ones, so a correct
+		// implementation still has to reorder before yielding.
+		time.Sleep(time.Duration(totalItems-start) * time.Microsecond)
+
+		end := start + size
+		if end > totalItems {
+			end = totalItems
+		}
+		items := make([]int, 0, end-start)
+		for i := start; i < end; i++ {
+			items = append(items, i)
+		}
+		return items, totalItems, nil
+	}
+
+	seq := iteratePages(context.Background(), IterateOptions{BatchSize: pageSize, Concurrency: 4}, fetch)
+
+	got, err := Collect(seq)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(got) != totalItems {
+		t.Fatalf("got %d items, want %d", len(got), totalItems)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("items out of order: got[%d] = %d, want %d", i, v, i)
+		}
+	}
+	if atomic.LoadInt32(&maxInFlight) < 2 {
+		t.Errorf("expected more than one page to be fetched concurrently, max in flight = %d", maxInFlight)
+	}
+}
+
+func TestIteratePagesConcurrentPropagatesError(t *testing.T) {
+	boom := context.DeadlineExceeded
+	fetch := func(ctx context.Context, start, size int) ([]int, int, error) {
+		if start == 0 {
+			return []int{1, 2}, 10, nil
+		}
+		return nil, 0, boom
+	}
+
+	seq := iteratePages(context.Background(), IterateOptions{BatchSize: 2, Concurrency: 2}, fetch)
+	_, err := Collect(seq)
+	if err != boom {
+		t.Fatalf("got err %v, want %v", err, boom)
+	}
+}
+
+func TestCollectAllStopsAtMax(t *testing.T) {
+	fetch := func(ctx context.Context, start, size int) ([]int, int, error) {
+		items := []int{start, start + 1}
+		return items, 100, nil
+	}
+
+	seq := iteratePages(context.Background(), IterateOptions{BatchSize: 2}, fetch)
+	got, err := CollectAll(seq, 3)
+	if err != nil {
+		t.Fatalf("CollectAll: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d items, want 3", len(got))
+	}
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithLogger adds a TransportOption that emits one structured log record per
+// request via logger, recording the operation, HTTP status, latency, and
+// outcome. Pair it with WithRetry/WithRateLimit/WithBreaker on
+// NewResilientClient. A nil logger is a no-op, so opting out costs nothing.
+func WithLogger(logger *slog.Logger) TransportOption {
+	return func(c *transportConfig) {
+		if logger == nil {
+			return
+		}
+		c.middlewares = append(c.middlewares, newLoggingMiddleware(logger))
+	}
+}
+
+func newLoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			operation, _ := OperationNameFromContext(req.Context())
+
+			resp, err := next.RoundTrip(req)
+
+			attrs := []any{
+				slog.String("rpc.system", "remnawave"),
+				slog.String("rpc.method", string(operation)),
+				slog.String("http.method", req.Method),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if resp != nil {
+				attrs = append(attrs, slog.Int("http.status_code", resp.StatusCode))
+			}
+			if reqID := req.Header.Get("X-Request-Id"); reqID != "" {
+				attrs = append(attrs, slog.String("remnawave.request_id", reqID))
+			}
+
+			switch {
+			case err != nil:
+				logger.LogAttrs(req.Context(), slog.LevelError, "remnawave api request failed", append(attrs, slog.String("error", err.Error()))...)
+			case resp != nil && resp.StatusCode >= 500:
+				logger.LogAttrs(req.Context(), slog.LevelWarn, "remnawave api request returned server error", attrs...)
+			default:
+				logger.LogAttrs(req.Context(), slog.LevelDebug, "remnawave api request completed", attrs...)
+			}
+
+			return resp, err
+		})
+	}
+}
+
+// operationNameCtxKey is the context key withOperationName stores the
+// in-flight OperationName under.
+type operationNameCtxKey struct{}
+
+// OperationNameFromContext returns the OperationName of the in-flight call,
+// if any. Every ClientExt sub-client method that currently has a real
+// implementation (client_ext.go) sets it via withOperationName before
+// delegating to the generated client, as do the hand-written helpers that
+// bypass ClientExt (iterator.go, timeout.go, bulk.go), so transport
+// middlewares and security sources can use it for attribution. ClientExt
+// methods that are still unimplemented stubs (e.g. ConfigProfileClient,
+// NodesClient) carry no OperationName yet; wire it in alongside their
+// delegate call when those are implemented.
+func OperationNameFromContext(ctx context.Context) (OperationName, bool) {
+	name, ok := ctx.Value(operationNameCtxKey{}).(OperationName)
+	return name, ok
+}
+
+// withOperationName stores name in ctx under the key OperationNameFromContext
+// reads.
+func withOperationName(ctx context.Context, name OperationName) context.Context {
+	return context.WithValue(ctx, operationNameCtxKey{}, name)
+}
+
+// WithTracerProvider adds a TransportOption that starts a span per request
+// via provider, carrying attributes rpc.system, rpc.method, http.status_code,
+// and remnawave.request_id. A nil provider is a no-op, so opting out costs
+// nothing.
+func WithTracerProvider(provider trace.TracerProvider) TransportOption {
+	return func(c *transportConfig) {
+		if provider == nil {
+			return
+		}
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider adds a TransportOption that records a per-operation
+// latency histogram and an error-rate counter via provider. A nil provider
+// is a no-op, so opting out costs nothing.
+func WithMeterProvider(provider metric.MeterProvider) TransportOption {
+	return func(c *transportConfig) {
+		if provider == nil {
+			return
+		}
+		c.meterProvider = provider
+	}
+}
+
+// newOtelMiddleware returns a Middleware that instruments requests with
+// tracerProvider and meterProvider. Either argument may be nil, in which
+// case the corresponding instrumentation is skipped.
+func newOtelMiddleware(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) Middleware {
+	var tracer trace.Tracer
+	if tracerProvider != nil {
+		tracer = tracerProvider.Tracer("remnawave-api-go")
+	}
+
+	var latency metric.Float64Histogram
+	var errors metric.Int64Counter
+	if meterProvider != nil {
+		meter := meterProvider.Meter("remnawave-api-go")
+		latency, _ = meter.Float64Histogram("remnawave.request.duration",
+			metric.WithDescription("Latency of remnawave API requests"), metric.WithUnit("s"))
+		errors, _ = meter.Int64Counter("remnawave.request.errors",
+			metric.WithDescription("Count of failed remnawave API requests"))
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			operation, _ := OperationNameFromContext(ctx)
+
+			var span trace.Span
+			if tracer != nil {
+				ctx, span = tracer.Start(ctx, "remnawave."+string(operation), trace.WithAttributes(
+					attribute.String("rpc.system", "remnawave"),
+					attribute.String("rpc.service", controllerFromOperation(operation)),
+					attribute.String("rpc.method", string(operation)),
+				))
+				req = req.WithContext(ctx)
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			failed := err != nil
+
+			if span != nil {
+				if resp != nil {
+					span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+					failed = failed || resp.StatusCode >= 500
+				}
+				if reqID := req.Header.Get("X-Request-Id"); reqID != "" {
+					span.SetAttributes(attribute.String("remnawave.request_id", reqID))
+				}
+				if err != nil {
+					span.RecordError(err)
+				}
+				span.End()
+			}
+
+			if latency != nil {
+				latency.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+					attribute.String("rpc.method", string(operation)),
+				))
+			}
+			if errors != nil && failed {
+				errors.Add(ctx, 1, metric.WithAttributes(
+					attribute.String("rpc.method", string(operation)),
+				))
+			}
+
+			return resp, err
+		})
+	}
+}
+
+// controllerFromOperation derives the rpc.service attribute from an
+// OperationName following the generated client's
+// `<Controller>Controller<Action>` naming convention, e.g.
+// "UsersControllerGetAllUsers" -> "Users".
+func controllerFromOperation(operation OperationName) string {
+	name := string(operation)
+	if idx := strings.Index(name, "Controller"); idx >= 0 {
+		return name[:idx]
+	}
+	return name
+}
@@ -0,0 +1,630 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware wraps an http.RoundTripper with additional behavior, mirroring
+// the standard library's convention for composing http.Handler chains.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// TransportOption configures the transport chain built by NewResilientClient
+// and NewResilientClientExt.
+type TransportOption func(*transportConfig)
+
+type transportConfig struct {
+	base           http.RoundTripper
+	middlewares    []Middleware
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+func (c *transportConfig) build() http.RoundTripper {
+	rt := c.base
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	middlewares := c.middlewares
+	if c.tracerProvider != nil || c.meterProvider != nil {
+		// Innermost, so each actual HTTP attempt (including retries) gets
+		// its own span and latency sample rather than one span per
+		// resilience-wrapped operation.
+		middlewares = append(middlewares, newOtelMiddleware(c.tracerProvider, c.meterProvider))
+	}
+
+	// Apply in reverse so the first option supplied is the outermost layer,
+	// i.e. WithRetry(...), WithRateLimit(...) retries the rate-limited call.
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// WithRetry adds exponential-backoff retry behavior to the transport chain.
+func WithRetry(policy RetryPolicy) TransportOption {
+	return func(c *transportConfig) {
+		c.middlewares = append(c.middlewares, NewRetryMiddleware(policy))
+	}
+}
+
+// WithRateLimit adds a client-side token-bucket rate limiter, keyed per host,
+// to the transport chain.
+func WithRateLimit(cfg RateLimitConfig) TransportOption {
+	return func(c *transportConfig) {
+		c.middlewares = append(c.middlewares, NewRateLimitMiddleware(cfg))
+	}
+}
+
+// WithBreaker adds a circuit breaker to the transport chain.
+func WithBreaker(cfg BreakerConfig) TransportOption {
+	return func(c *transportConfig) {
+		c.middlewares = append(c.middlewares, NewBreakerMiddleware(cfg))
+	}
+}
+
+// WithBaseTransport overrides the innermost http.RoundTripper that the
+// resilience middlewares wrap. Defaults to http.DefaultTransport.
+func WithBaseTransport(rt http.RoundTripper) TransportOption {
+	return func(c *transportConfig) { c.base = rt }
+}
+
+// NewResilientClient builds a base Client whose underlying HTTP transport
+// applies the given resilience middlewares (retry, rate-limiting, circuit
+// breaking) around the usual network round trip. Use this instead of
+// NewClient directly when talking to a panel behind a flaky network.
+func NewResilientClient(serverURL string, security SecuritySource, opts ...TransportOption) (*Client, error) {
+	cfg := &transportConfig{base: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	httpClient := &http.Client{Transport: cfg.build()}
+	return NewClient(serverURL, security, WithClient(httpClient))
+}
+
+// NewResilientClientExt is the ClientExt equivalent of NewResilientClient.
+func NewResilientClientExt(serverURL string, security SecuritySource, opts ...TransportOption) (*ClientExt, error) {
+	client, err := NewResilientClient(serverURL, security, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientExt(client), nil
+}
+
+// RetryPolicy configures NewRetryMiddleware.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay.
+	MaxDelay time.Duration
+	// Methods lists the HTTP methods eligible for retry. Defaults to the
+	// idempotent set (GET, PUT, DELETE, HEAD, OPTIONS) when nil.
+	Methods []string
+	// AllowedOperations additionally permits retrying POST operations the
+	// caller has marked safe (e.g. ones that are idempotent server-side),
+	// identified via OperationNameFromContext.
+	AllowedOperations []OperationName
+	// Jitter enables full-jitter randomization of the computed backoff
+	// delay, spreading out retries from many clients reconnecting at once.
+	Jitter bool
+	// OnRetry, if set, is called before each retry sleep for observability,
+	// composing with OpenTelemetry tracing/metrics set up elsewhere.
+	OnRetry func(attempt int, err error, nextDelay time.Duration)
+	// Breaker, if set, trips a per-host circuit after this many consecutive
+	// failures and short-circuits further requests to that host until
+	// CoolDown elapses. It is checked once per request, before the first
+	// attempt; retries of a request already admitted still go through.
+	// This is a simpler alternative to NewBreakerMiddleware's sliding
+	// error-rate window, scoped to the retry loop itself.
+	Breaker *ConsecutiveBreakerConfig
+	// PerAttemptTimeout, if set, bounds each individual attempt rather than
+	// the retry loop as a whole, so a single slow attempt can time out and
+	// be retried within the operation's overall deadline (e.g. the one
+	// WithDefaultTimeouts applies) instead of consuming all of it.
+	PerAttemptTimeout time.Duration
+}
+
+// ConsecutiveBreakerConfig configures the per-host consecutive-failure
+// breaker mode of NewRetryMiddleware.
+type ConsecutiveBreakerConfig struct {
+	// Threshold is the number of consecutive failures to a host that trips
+	// the breaker. Defaults to 5.
+	Threshold int
+	// CoolDown is how long the breaker stays open before allowing another
+	// attempt through. Defaults to 30s.
+	CoolDown time.Duration
+}
+
+func (c ConsecutiveBreakerConfig) threshold() int {
+	if c.Threshold > 0 {
+		return c.Threshold
+	}
+	return 5
+}
+
+func (c ConsecutiveBreakerConfig) coolDown() time.Duration {
+	if c.CoolDown > 0 {
+		return c.CoolDown
+	}
+	return 30 * time.Second
+}
+
+// consecutiveBreaker tracks per-host consecutive-failure state for
+// NewRetryMiddleware's optional Breaker mode.
+type consecutiveBreaker struct {
+	mu     sync.Mutex
+	cfg    ConsecutiveBreakerConfig
+	byHost map[string]*consecutiveBreakerState
+}
+
+type consecutiveBreakerState struct {
+	consecutiveFailures int
+	openedAt            time.Time
+}
+
+func newConsecutiveBreaker(cfg ConsecutiveBreakerConfig) *consecutiveBreaker {
+	return &consecutiveBreaker{cfg: cfg, byHost: make(map[string]*consecutiveBreakerState)}
+}
+
+func (b *consecutiveBreaker) allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.byHost[host]
+	if !ok || state.consecutiveFailures < b.cfg.threshold() {
+		return true
+	}
+	return time.Since(state.openedAt) >= b.cfg.coolDown()
+}
+
+func (b *consecutiveBreaker) record(host string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.byHost[host]
+	if !ok {
+		state = &consecutiveBreakerState{}
+		b.byHost[host] = state
+	}
+	if !failed {
+		state.consecutiveFailures = 0
+		return
+	}
+	state.consecutiveFailures++
+	if state.consecutiveFailures >= b.cfg.threshold() {
+		state.openedAt = time.Now()
+	}
+}
+
+func (p RetryPolicy) methods() map[string]bool {
+	methods := p.Methods
+	if methods == nil {
+		methods = []string{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodHead, http.MethodOptions}
+	}
+	set := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		set[m] = true
+	}
+	return set
+}
+
+func (p RetryPolicy) allowedOperations() map[OperationName]bool {
+	set := make(map[OperationName]bool, len(p.AllowedOperations))
+	for _, op := range p.AllowedOperations {
+		set[op] = true
+	}
+	return set
+}
+
+// retryableStatusCodes are the status codes retried in addition to a plain
+// network error, per the policy's idempotency rules.
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// NewRetryMiddleware returns a Middleware that retries failed requests with
+// exponential backoff and full-jitter randomization when policy.Jitter is
+// set. A request is retried when it fails with a network error or a
+// 429/502/503/504 status, provided its method is idempotent per policy (or
+// its operation is explicitly allow-listed for POST), and it honors a
+// `Retry-After` header in both the seconds and HTTP-date forms.
+func NewRetryMiddleware(policy RetryPolicy) Middleware {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 200 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 10 * time.Second
+	}
+	allowedMethods := policy.methods()
+	allowedOps := policy.allowedOperations()
+	var breaker *consecutiveBreaker
+	if policy.Breaker != nil {
+		breaker = newConsecutiveBreaker(*policy.Breaker)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !allowedMethods[req.Method] {
+				op, _ := OperationNameFromContext(req.Context())
+				if !allowedOps[op] {
+					return next.RoundTrip(req)
+				}
+			}
+
+			host := req.URL.Host
+			if breaker != nil && !breaker.allow(host) {
+				return nil, errBreakerOpen
+			}
+
+			var resp *http.Response
+			var err error
+			for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+				if attempt > 0 {
+					delay := retryDelay(policy, attempt, resp)
+					if policy.OnRetry != nil {
+						policy.OnRetry(attempt, err, delay)
+					}
+					if resp != nil {
+						resp.Body.Close()
+					}
+					if werr := sleepContext(req.Context(), delay); werr != nil {
+						return nil, werr
+					}
+				}
+
+				attemptReq := req
+				if attempt > 0 && req.Body != nil {
+					if req.GetBody == nil {
+						return nil, fmt.Errorf("api: cannot retry %s %s: request body is not replayable", req.Method, req.URL)
+					}
+					body, berr := req.GetBody()
+					if berr != nil {
+						return nil, fmt.Errorf("api: rewind request body for retry: %w", berr)
+					}
+					attemptReq = req.Clone(req.Context())
+					attemptReq.Body = body
+				}
+				cancelAttempt := context.CancelFunc(func() {})
+				if policy.PerAttemptTimeout > 0 {
+					attemptCtx, cancel := context.WithTimeout(attemptReq.Context(), policy.PerAttemptTimeout)
+					attemptReq = attemptReq.WithContext(attemptCtx)
+					cancelAttempt = cancel
+				}
+
+				resp, err = next.RoundTrip(attemptReq)
+				if resp != nil && policy.PerAttemptTimeout > 0 {
+					// Keep the attempt's context alive until its body is closed,
+					// so the caller can still read a response this returns.
+					resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancelAttempt}
+				} else {
+					cancelAttempt()
+				}
+				failed := err != nil || retryableStatusCodes[resp.StatusCode]
+				if breaker != nil {
+					breaker.record(host, failed)
+				}
+				if err == nil && !retryableStatusCodes[resp.StatusCode] {
+					return resp, nil
+				}
+				if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+					continue
+				}
+				// A DeadlineExceeded/Canceled error here may belong to the
+				// per-attempt context PerAttemptTimeout derived above rather
+				// than to req's own context, in which case it just means
+				// this attempt was slow and the next one deserves a try, not
+				// that the whole operation should give up. Only the outer
+				// context being done means the caller itself gave up.
+				if err != nil && req.Context().Err() == nil {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			return resp, err
+		})
+	}
+}
+
+// retryDelay computes the exponential-backoff delay for the given attempt,
+// deferring to a `Retry-After` header (seconds or HTTP-date form) on the
+// previous response when set, and applying full jitter when configured.
+func retryDelay(policy RetryPolicy, attempt int, prev *http.Response) time.Duration {
+	if prev != nil {
+		if ra := prev.Header.Get("Retry-After"); ra != "" {
+			if secs, err := strconv.Atoi(ra); err == nil {
+				return time.Duration(secs) * time.Second
+			}
+			if at, err := http.ParseTime(ra); err == nil {
+				if d := time.Until(at); d > 0 {
+					return d
+				}
+			}
+		}
+	}
+	delay := expBackoff(policy.BaseDelay, policy.MaxDelay, attempt-1)
+	if policy.Jitter {
+		delay = jitter(delay)
+	}
+	return delay
+}
+
+// expBackoff computes base*2^attempt, capped at max. It's shared by the
+// transport-level RetryPolicy above and the bulk chunk retry in bulk.go so
+// both back off the same way.
+func expBackoff(base, max time.Duration, attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	delay := time.Duration(float64(base) * math.Pow(2, float64(attempt)))
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// sleepContext sleeps for d or returns ctx.Err() if ctx is canceled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RateLimitConfig configures NewRateLimitMiddleware.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate of the token bucket.
+	RequestsPerSecond float64
+	// Burst is the maximum number of requests allowed in a single burst.
+	Burst int
+}
+
+// NewRateLimitMiddleware returns a Middleware enforcing a client-side
+// token-bucket rate limit per request host, so bursts against one panel
+// don't starve requests to another.
+func NewRateLimitMiddleware(cfg RateLimitConfig) Middleware {
+	if cfg.RequestsPerSecond <= 0 {
+		cfg.RequestsPerSecond = 10
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = int(cfg.RequestsPerSecond)
+	}
+
+	limiters := &hostLimiters{
+		cfg:    cfg,
+		byHost: make(map[string]*tokenBucket),
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := limiters.forHost(req.URL.Host).wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+type hostLimiters struct {
+	mu     sync.Mutex
+	cfg    RateLimitConfig
+	byHost map[string]*tokenBucket
+}
+
+func (h *hostLimiters) forHost(host string) *tokenBucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.byHost[host]
+	if !ok {
+		b = newTokenBucket(h.cfg.RequestsPerSecond, h.cfg.Burst)
+		h.byHost[host] = b
+	}
+	return b
+}
+
+// tokenBucket is a minimal token-bucket limiter; refill happens lazily on
+// each wait() call rather than via a background goroutine.
+type tokenBucket struct {
+	mu         sync.Mutex
+	ratePerSec float64
+	burst      float64
+	tokens     float64
+	last       time.Time
+}
+
+func newTokenBucket(ratePerSec float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		ratePerSec: ratePerSec,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		last:       time.Now(),
+	}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.ratePerSec)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		missing := 1 - b.tokens
+		wait := time.Duration(missing / b.ratePerSec * float64(time.Second))
+		b.mu.Unlock()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+// BreakerConfig configures NewBreakerMiddleware.
+type BreakerConfig struct {
+	// Window is the sliding window over which the error rate is computed.
+	Window time.Duration
+	// MinRequests is the minimum number of requests in Window before the
+	// error rate is evaluated.
+	MinRequests int
+	// ErrorRateThreshold trips the breaker once reached, e.g. 0.5 for 50%.
+	ErrorRateThreshold float64
+	// CoolDown is how long the breaker stays open before probing again.
+	CoolDown time.Duration
+}
+
+var errBreakerOpen = errors.New("api: circuit breaker open")
+
+// NewBreakerMiddleware returns a Middleware implementing a closed -> open ->
+// half-open circuit breaker that trips once the error rate over a sliding
+// window reaches ErrorRateThreshold, short-circuiting requests while open.
+func NewBreakerMiddleware(cfg BreakerConfig) Middleware {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 10
+	}
+	if cfg.ErrorRateThreshold <= 0 {
+		cfg.ErrorRateThreshold = 0.5
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = 30 * time.Second
+	}
+
+	b := &breaker{cfg: cfg}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !b.allow() {
+				return nil, errBreakerOpen
+			}
+			resp, err := next.RoundTrip(req)
+			b.record(err != nil || resp.StatusCode >= 500)
+			return resp, err
+		})
+	}
+}
+
+type breaker struct {
+	mu               sync.Mutex
+	cfg              BreakerConfig
+	state            BreakerState
+	openedAt         time.Time
+	events           []breakerEvent
+	halfOpenInFlight bool
+}
+
+type breakerEvent struct {
+	at     time.Time
+	failed bool
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cfg.CoolDown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.halfOpenInFlight = true
+		return true
+	case BreakerHalfOpen:
+		if b.halfOpenInFlight {
+			return false
+		}
+		b.halfOpenInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.halfOpenInFlight = false
+		if failed {
+			b.trip()
+		} else {
+			b.state = BreakerClosed
+			b.events = nil
+		}
+		return
+	}
+
+	now := time.Now()
+	b.events = append(b.events, breakerEvent{at: now, failed: failed})
+	cutoff := now.Add(-b.cfg.Window)
+	kept := b.events[:0]
+	var failures int
+	for _, e := range b.events {
+		if e.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, e)
+		if e.failed {
+			failures++
+		}
+	}
+	b.events = kept
+
+	if len(b.events) >= b.cfg.MinRequests && float64(failures)/float64(len(b.events)) >= b.cfg.ErrorRateThreshold {
+		b.trip()
+	}
+}
+
+func (b *breaker) trip() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.events = nil
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// jitter returns a random delay in [0, d), i.e. full-jitter backoff on top of
+// the base exponential delay.
+func jitter(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * rand.Float64())
+}
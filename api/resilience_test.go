@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "http://example.invalid/thing", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	return req
+}
+
+// TestNewRetryMiddlewareReplaysBody verifies that a retried attempt resends
+// the original request body rather than the drained reader left behind by
+// the first attempt.
+func TestNewRetryMiddlewareReplaysBody(t *testing.T) {
+	const want = "hello world"
+
+	var bodies []string
+	var calls int
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+		bodies = append(bodies, string(data))
+
+		status := http.StatusServiceUnavailable
+		if calls == 2 {
+			status = http.StatusOK
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+		}, nil
+	})
+
+	mw := NewRetryMiddleware(RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		Methods:     []string{http.MethodPost},
+	})
+
+	resp, err := mw(next).RoundTrip(newTestRequest(t, want))
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if calls != 2 {
+		t.Fatalf("got %d attempts, want 2", calls)
+	}
+	for i, got := range bodies {
+		if got != want {
+			t.Errorf("attempt %d body = %q, want %q", i+1, got, want)
+		}
+	}
+}
+
+// TestNewRetryMiddlewareClosesDiscardedResponseBody verifies that a
+// retryable response's body is closed before the next attempt is issued.
+func TestNewRetryMiddlewareClosesDiscardedResponseBody(t *testing.T) {
+	var first *closeTrackingBody
+	var calls int
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			first = &closeTrackingBody{Reader: bytes.NewReader(nil)}
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header), Body: first}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	})
+
+	mw := NewRetryMiddleware(RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		Methods:     []string{http.MethodPost},
+	})
+
+	resp, err := mw(next).RoundTrip(newTestRequest(t, "body"))
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if first == nil || !first.closed {
+		t.Errorf("first response body was not closed before retrying")
+	}
+}
+
+type closeTrackingBody struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// TestBreakerStateTransitions verifies the closed -> open -> half-open ->
+// closed lifecycle of the sliding-window breaker.
+func TestBreakerStateTransitions(t *testing.T) {
+	b := &breaker{cfg: BreakerConfig{
+		Window:             time.Minute,
+		MinRequests:        2,
+		ErrorRateThreshold: 0.5,
+		CoolDown:           10 * time.Millisecond,
+	}}
+
+	if !b.allow() {
+		t.Fatal("breaker should allow requests while closed")
+	}
+	b.record(true)
+	b.record(true)
+	if b.state != BreakerOpen {
+		t.Fatalf("state = %v, want BreakerOpen after exceeding error threshold", b.state)
+	}
+
+	if b.allow() {
+		t.Fatal("breaker should not allow requests immediately after opening")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("breaker should allow a probe request once the cooldown elapses")
+	}
+	if b.state != BreakerHalfOpen {
+		t.Fatalf("state = %v, want BreakerHalfOpen after cooldown", b.state)
+	}
+
+	if b.allow() {
+		t.Fatal("breaker should not allow a second concurrent probe while half-open")
+	}
+
+	b.record(false)
+	if b.state != BreakerClosed {
+		t.Fatalf("state = %v, want BreakerClosed after a successful probe", b.state)
+	}
+}
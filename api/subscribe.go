@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// SubscribeOptions configures the polling fallback used by SubscribeMetrics
+// and Watch when the panel has no push channel available.
+type SubscribeOptions struct {
+	// PollInterval is how often the underlying REST endpoint is polled.
+	// Defaults to 5s when zero.
+	PollInterval time.Duration
+	// ReconnectBackoff is the base delay used when a poll or stream attempt
+	// fails and needs to be retried. Defaults to 1s when zero, and doubles
+	// up to a 30s ceiling on successive failures.
+	ReconnectBackoff time.Duration
+}
+
+func (o SubscribeOptions) pollInterval() time.Duration {
+	if o.PollInterval > 0 {
+		return o.PollInterval
+	}
+	return 5 * time.Second
+}
+
+func (o SubscribeOptions) reconnectBackoff() time.Duration {
+	if o.ReconnectBackoff > 0 {
+		return o.ReconnectBackoff
+	}
+	return time.Second
+}
+
+// MetricEvent is a single sample delivered by SubscribeMetrics.
+type MetricEvent struct {
+	Metrics *NodesMetricsResponse
+	Err     error
+}
+
+// SubscribeMetrics streams node metrics, polling GetNodesMetrics on
+// opts.PollInterval and de-duplicating identical snapshots. The returned
+// channel closes only when ctx is done. A failed poll is delivered as an
+// event with a non-nil Err and retried with exponential backoff rather than
+// ending the stream.
+func (sc *SystemClient) SubscribeMetrics(ctx context.Context, opts SubscribeOptions) (<-chan MetricEvent, error) {
+	events := make(chan MetricEvent)
+
+	go func() {
+		defer close(events)
+
+		backoff := opts.reconnectBackoff()
+		var lastCursor string
+		for {
+			resp, err := sc.client.SystemControllerGetNodesMetrics(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !emitAndWait(ctx, events, MetricEvent{Err: err}, backoff) {
+					return
+				}
+				backoff = nextBackoff(backoff)
+				continue
+			}
+			backoff = opts.reconnectBackoff()
+
+			metrics, ok := resp.(*NodesMetricsResponse)
+			if !ok {
+				if !emitAndWait(ctx, events, MetricEvent{Err: newUnexpectedResponseError("SystemControllerGetNodesMetrics", resp)}, opts.pollInterval()) {
+					return
+				}
+				continue
+			}
+
+			if cursor := metrics.UpdatedAt; cursor != lastCursor {
+				lastCursor = cursor
+				select {
+				case events <- MetricEvent{Metrics: metrics}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-time.After(opts.pollInterval()):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// UserEventType identifies what changed about a user in a UserEvent.
+type UserEventType string
+
+const (
+	UserEventCreated UserEventType = "created"
+	UserEventUpdated UserEventType = "updated"
+	UserEventDeleted UserEventType = "deleted"
+)
+
+// UserEvent is a single change delivered by UsersClient.Watch.
+type UserEvent struct {
+	Type UserEventType
+	User *UserItem
+	Err  error
+}
+
+// UserFilter narrows which users Watch reports on.
+type UserFilter struct {
+	Tag string
+}
+
+// Watch streams user create/update/delete events, polling GetAllUsers on
+// opts.PollInterval and diffing by `updatedAt` against the last-seen set.
+// The returned channel closes only when ctx is done. A failed poll is
+// delivered as an event with a non-nil Err and retried with exponential
+// backoff rather than ending the stream.
+func (sc *UsersClient) Watch(ctx context.Context, filter UserFilter, opts SubscribeOptions) (<-chan UserEvent, error) {
+	events := make(chan UserEvent)
+
+	go func() {
+		defer close(events)
+
+		backoff := opts.reconnectBackoff()
+		seen := make(map[string]string) // uuid -> updatedAt cursor
+
+		for {
+			users, err := Collect(sc.IterateAll(ctx, IterateOptions{}))
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !emitAndWait(ctx, events, UserEvent{Err: err}, backoff) {
+					return
+				}
+				backoff = nextBackoff(backoff)
+				continue
+			}
+			backoff = opts.reconnectBackoff()
+
+			current := make(map[string]string, len(users))
+			for _, u := range users {
+				if filter.Tag != "" && u.Tag != filter.Tag {
+					continue
+				}
+				current[u.UUID] = u.UpdatedAt
+
+				cursor, known := seen[u.UUID]
+				switch {
+				case !known:
+					if !emit(ctx, events, UserEvent{Type: UserEventCreated, User: u}) {
+						return
+					}
+				case cursor != u.UpdatedAt:
+					if !emit(ctx, events, UserEvent{Type: UserEventUpdated, User: u}) {
+						return
+					}
+				}
+			}
+			for uuid := range seen {
+				if _, ok := current[uuid]; !ok {
+					if !emit(ctx, events, UserEvent{Type: UserEventDeleted, User: &UserItem{UUID: uuid}}) {
+						return
+					}
+				}
+			}
+			seen = current
+
+			select {
+			case <-time.After(opts.pollInterval()):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func emit[T any](ctx context.Context, events chan<- T, ev T) bool {
+	select {
+	case events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func emitAndWait[T any](ctx context.Context, events chan<- T, ev T, wait time.Duration) bool {
+	if !emit(ctx, events, ev) {
+		return false
+	}
+	select {
+	case <-time.After(wait):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func nextBackoff(d time.Duration) time.Duration {
+	const ceiling = 30 * time.Second
+	next := d * 2
+	if next > ceiling {
+		return ceiling
+	}
+	return next
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultReadTimeout is the deadline applied to read-only operations (list,
+// get) when the incoming ctx carries no deadline of its own. Zero disables
+// the default.
+var DefaultReadTimeout time.Duration
+
+// DefaultWriteTimeout is the deadline applied to mutating operations
+// (create, update, delete) when the incoming ctx carries no deadline of its
+// own. Zero disables the default.
+var DefaultWriteTimeout time.Duration
+
+// TimeoutError reports that a client-side deadline elapsed before the
+// operation completed, distinguishing it from a server-side 504.
+type TimeoutError struct {
+	Operation string
+	Timeout   time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("api: %s timed out after %s", e.Operation, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error { return context.DeadlineExceeded }
+
+// withOperationDeadline wraps ctx with context.WithTimeout only if ctx has
+// no deadline of its own and d is positive, otherwise it returns ctx
+// unchanged with a no-op cancel.
+func withOperationDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// asTimeoutError converts a context.DeadlineExceeded returned by an
+// operation into a *TimeoutError carrying the operation name, leaving any
+// other error untouched.
+func asTimeoutError(operation string, timeout time.Duration, err error) error {
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return &TimeoutError{Operation: operation, Timeout: timeout}
+	}
+	return err
+}
+
+// TimedUsersClient applies a fixed per-call deadline to its GetAllUsers and
+// DeleteUser methods before delegating to the wrapped UsersClient, converting
+// a resulting context.DeadlineExceeded into a *TimeoutError. Other methods
+// promoted from the embedded UsersClient run without that deadline.
+type TimedUsersClient struct {
+	*UsersClient
+	timeout time.Duration
+}
+
+// WithTimeout returns a shallow copy of sc that applies d as a per-call
+// deadline; the option is not sticky on sc itself.
+func (sc *UsersClient) WithTimeout(d time.Duration) *TimedUsersClient {
+	return &TimedUsersClient{UsersClient: sc, timeout: d}
+}
+
+// WithDeadline returns a shallow copy of tc that applies t as a per-call
+// deadline instead of tc's current one; the option is not sticky on tc
+// itself.
+func (tc *TimedUsersClient) WithDeadline(t time.Time) *TimedUsersClient {
+	return &TimedUsersClient{UsersClient: tc.UsersClient, timeout: time.Until(t)}
+}
+
+// GetAllUsers applies tc's timeout before delegating to the wrapped
+// UsersClient.
+func (tc *TimedUsersClient) GetAllUsers(ctx context.Context, start, size int) (UsersControllerGetAllUsersRes, error) {
+	timeout := tc.timeout
+	if timeout <= 0 {
+		timeout = DefaultReadTimeout
+	}
+	ctx, cancel := withOperationDeadline(ctx, timeout)
+	defer cancel()
+
+	resp, err := tc.client.UsersControllerGetAllUsers(withOperationName(ctx, "UsersControllerGetAllUsers"), UsersControllerGetAllUsersParams{Start: start, Size: size})
+	return resp, asTimeoutError("UsersControllerGetAllUsers", timeout, err)
+}
+
+// DeleteUser applies tc's timeout (or DefaultWriteTimeout) before deleting
+// the user identified by uuid, converting a resulting
+// context.DeadlineExceeded into a *TimeoutError. Unlike GetAllUsers, this is
+// a mutating call, so it falls back to DefaultWriteTimeout rather than
+// DefaultReadTimeout when tc carries no timeout of its own.
+func (tc *TimedUsersClient) DeleteUser(ctx context.Context, uuid string) (UsersControllerBulkDeleteUsersRes, error) {
+	timeout := tc.timeout
+	if timeout <= 0 {
+		timeout = DefaultWriteTimeout
+	}
+	ctx, cancel := withOperationDeadline(ctx, timeout)
+	defer cancel()
+
+	resp, err := tc.client.UsersControllerBulkDeleteUsers(withOperationName(ctx, "UsersControllerBulkDeleteUsers"), &BulkUuidsRequest{Uuids: []string{uuid}})
+	return resp, asTimeoutError("UsersControllerBulkDeleteUsers", timeout, err)
+}
+
+// WithDefaultTimeouts adds a TransportOption that applies a per-operation
+// deadline, keyed by OperationName, to requests that don't already carry
+// one — so expensive endpoints (GetNodesStatistics, GetBandwidthStats) can
+// be given longer defaults than cheap ones (GetStatus) without every caller
+// threading a context.WithTimeout through by hand. This timeout bounds the
+// *total* operation including retries; use RetryPolicy.PerAttemptTimeout
+// instead (or in addition) to bound each individual attempt.
+func WithDefaultTimeouts(timeouts map[OperationName]time.Duration) TransportOption {
+	return func(c *transportConfig) {
+		// Prepended rather than appended, so it wraps every other middleware
+		// (WithRetry included) whatever order the options are supplied in.
+		c.middlewares = append([]Middleware{func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				op, ok := OperationNameFromContext(req.Context())
+				d, hasTimeout := timeouts[op]
+				if !ok || !hasTimeout {
+					return next.RoundTrip(req)
+				}
+
+				ctx, cancel := withOperationDeadline(req.Context(), d)
+				resp, err := next.RoundTrip(req.WithContext(ctx))
+				if err != nil {
+					cancel()
+					return resp, asTimeoutError(string(op), d, err)
+				}
+				resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+				return resp, nil
+			})
+		}}, c.middlewares...)
+	}
+}
+
+// cancelOnCloseBody calls cancel once the wrapped response body is closed,
+// so a context derived for a request stays live while its body is read.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// WithCancelOn adds a TransportOption that short-circuits a long-running
+// polling response as soon as it arrives: shouldCancel inspects the
+// response and, if it returns true, the response body is closed and
+// ErrCanceledByPredicate is returned instead of the response, letting a
+// caller bail out of e.g. SubscribeMetrics without waiting for ctx to
+// actually expire.
+func WithCancelOn(shouldCancel func(resp *http.Response) bool) TransportOption {
+	return func(c *transportConfig) {
+		c.middlewares = append(c.middlewares, func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.RoundTrip(req)
+				if err != nil || resp == nil {
+					return resp, err
+				}
+				if shouldCancel(resp) {
+					resp.Body.Close()
+					return nil, ErrCanceledByPredicate
+				}
+				return resp, nil
+			})
+		})
+	}
+}
+
+// ErrCanceledByPredicate is returned by a WithCancelOn transport when its
+// predicate matched the response.
+var ErrCanceledByPredicate = errors.New("api: request canceled by predicate")
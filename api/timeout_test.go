@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithOperationDeadline(t *testing.T) {
+	ctx, cancel := withOperationDeadline(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected a deadline to be set")
+	}
+	if time.Until(deadline) > 10*time.Millisecond {
+		t.Errorf("deadline too far in the future: %s", time.Until(deadline))
+	}
+}
+
+func TestWithOperationDeadlineKeepsExistingDeadline(t *testing.T) {
+	parent, parentCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer parentCancel()
+	want, _ := parent.Deadline()
+
+	ctx, cancel := withOperationDeadline(parent, time.Millisecond)
+	defer cancel()
+
+	got, ok := ctx.Deadline()
+	if !ok || !got.Equal(want) {
+		t.Errorf("deadline = %v, ok = %v, want unchanged parent deadline %v", got, ok, want)
+	}
+}
+
+func TestAsTimeoutError(t *testing.T) {
+	err := asTimeoutError("GetAllUsers", time.Second, context.DeadlineExceeded)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *TimeoutError, got %T", err)
+	}
+	if timeoutErr.Operation != "GetAllUsers" {
+		t.Errorf("Operation = %q, want %q", timeoutErr.Operation, "GetAllUsers")
+	}
+
+	other := errors.New("boom")
+	if got := asTimeoutError("GetAllUsers", time.Second, other); got != other {
+		t.Errorf("asTimeoutError should pass through non-deadline errors unchanged, got %v", got)
+	}
+}
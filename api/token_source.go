@@ -0,0 +1,366 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies a bearer token on demand, along with its expiry so
+// callers (and CachedTokenSource) know when to refresh. It generalizes the
+// single-token StaticToken for credentials that rotate over the client's
+// lifetime.
+type TokenSource interface {
+	Token(ctx context.Context) (token string, expiresAt time.Time, err error)
+}
+
+type tokenSourceSecurity struct {
+	source TokenSource
+}
+
+// AsSecuritySource adapts a TokenSource to the SecuritySource interface
+// expected by NewClient, so token rotation is transparent to the generated
+// client.
+func AsSecuritySource(source TokenSource) SecuritySource {
+	return tokenSourceSecurity{source: source}
+}
+
+func (s tokenSourceSecurity) Authorization(ctx context.Context, _ OperationName) (Authorization, error) {
+	token, _, err := s.source.Token(ctx)
+	if err != nil {
+		return Authorization{}, err
+	}
+	return Authorization{Token: token}, nil
+}
+
+// FileTokenSource reads the token from a file, reloading it whenever the
+// file's modification time changes. It polls rather than depending on
+// fsnotify so it has no third-party dependency; Interval defaults to 5s.
+type FileTokenSource struct {
+	Path     string
+	Interval time.Duration
+
+	mu        sync.Mutex
+	cached    string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func (f *FileTokenSource) interval() time.Duration {
+	if f.Interval > 0 {
+		return f.Interval
+	}
+	return 5 * time.Second
+}
+
+// Token returns the file's current contents. The file's mtime is checked at
+// most once per Interval, and the file is reloaded from disk only when its
+// mtime has changed since the last read.
+func (f *FileTokenSource) Token(_ context.Context) (string, time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.cached != "" && time.Since(f.checkedAt) < f.interval() {
+		return f.cached, time.Time{}, nil
+	}
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("api: stat token file: %w", err)
+	}
+	f.checkedAt = time.Now()
+
+	if info.ModTime().Equal(f.modTime) && f.cached != "" {
+		return f.cached, time.Time{}, nil
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("api: read token file: %w", err)
+	}
+
+	f.cached = trimToken(data)
+	f.modTime = info.ModTime()
+	return f.cached, time.Time{}, nil
+}
+
+func trimToken(data []byte) string {
+	for len(data) > 0 && (data[len(data)-1] == '\n' || data[len(data)-1] == '\r' || data[len(data)-1] == ' ') {
+		data = data[:len(data)-1]
+	}
+	return string(data)
+}
+
+// LoginCredentials are replayed against AuthClient.Login by
+// RefreshingTokenSource whenever the cached token expires.
+type LoginCredentials struct {
+	Username string
+	Password string
+}
+
+// RefreshingTokenSource calls AuthClient.Login on expiry using stored
+// credentials, caching the resulting token until it is about to expire.
+type RefreshingTokenSource struct {
+	Auth        *AuthClient
+	Credentials LoginCredentials
+	// Skew is how long before the reported expiry the token is considered
+	// stale, so a refresh can complete before the old token is rejected.
+	// Defaults to 30s.
+	Skew time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (r *RefreshingTokenSource) skew() time.Duration {
+	if r.Skew > 0 {
+		return r.Skew
+	}
+	return 30 * time.Second
+}
+
+// Token returns the cached token, transparently calling Auth.Login to
+// obtain a new one if the cached token is missing or within Skew of expiry.
+func (r *RefreshingTokenSource) Token(ctx context.Context) (string, time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.token != "" && time.Until(r.expiresAt) > r.skew() {
+		return r.token, r.expiresAt, nil
+	}
+
+	resp, err := r.Auth.Login(ctx, &LoginRequestDto{
+		Username: r.Credentials.Username,
+		Password: r.Credentials.Password,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("api: refresh token via login: %w", err)
+	}
+	login, ok := resp.(*LoginResponseDto)
+	if !ok {
+		return "", time.Time{}, newUnexpectedResponseError("AuthControllerLogin", resp)
+	}
+
+	r.token = login.Response.AccessToken
+	r.expiresAt = time.Now().Add(login.Response.ExpiresIn)
+	return r.token, r.expiresAt, nil
+}
+
+// CachedTokenSource wraps any TokenSource with in-memory TTL caching and
+// single-flight refresh: concurrent callers observing an expired token all
+// wait on the same in-flight refresh instead of each issuing one.
+type CachedTokenSource struct {
+	Source TokenSource
+
+	mu         sync.Mutex
+	token      string
+	expiresAt  time.Time
+	inflight   chan struct{}
+	refreshed  string
+	refreshAt  time.Time
+	refreshErr error
+}
+
+// Token returns the cached token if still fresh, otherwise triggers (or
+// joins) a single in-flight refresh against the wrapped TokenSource.
+func (c *CachedTokenSource) Token(ctx context.Context) (string, time.Time, error) {
+	c.mu.Lock()
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		token, expiresAt := c.token, c.expiresAt
+		c.mu.Unlock()
+		return token, expiresAt, nil
+	}
+
+	if c.inflight != nil {
+		wait := c.inflight
+		c.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return "", time.Time{}, ctx.Err()
+		}
+		c.mu.Lock()
+		token, expiresAt, err := c.refreshed, c.refreshAt, c.refreshErr
+		c.mu.Unlock()
+		return token, expiresAt, err
+	}
+
+	done := make(chan struct{})
+	c.inflight = done
+	c.mu.Unlock()
+
+	token, expiresAt, err := c.Source.Token(ctx)
+
+	c.mu.Lock()
+	if err == nil {
+		c.token, c.expiresAt = token, expiresAt
+	}
+	c.refreshed, c.refreshAt, c.refreshErr = token, expiresAt, err
+	c.inflight = nil
+	c.mu.Unlock()
+	close(done)
+
+	return token, expiresAt, err
+}
+
+// Invalidate forces the next Token call to refresh rather than serve from
+// cache, used by WithReauthOn401 after a request comes back unauthorized.
+func (c *CachedTokenSource) Invalidate() {
+	c.mu.Lock()
+	c.expiresAt = time.Time{}
+	c.mu.Unlock()
+}
+
+// LoginTokenSource calls AuthClient.Login with stored credentials and
+// refreshes whenever the issued JWT is about to expire, determining expiry
+// by decoding the token's own `exp` claim rather than trusting a separate
+// field on the login response. Prefer RefreshingTokenSource when the panel
+// instead reports an explicit ExpiresIn duration.
+type LoginTokenSource struct {
+	Auth        *AuthClient
+	Credentials LoginCredentials
+	// Skew is how long before the decoded expiry the token is considered
+	// stale. Defaults to 30s.
+	Skew time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (l *LoginTokenSource) skew() time.Duration {
+	if l.Skew > 0 {
+		return l.Skew
+	}
+	return 30 * time.Second
+}
+
+// Token returns the cached token, transparently calling Auth.Login to
+// obtain a new one if the cached token is missing or within Skew of the
+// expiry decoded from its `exp` claim.
+func (l *LoginTokenSource) Token(ctx context.Context) (string, time.Time, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token != "" && time.Until(l.expiresAt) > l.skew() {
+		return l.token, l.expiresAt, nil
+	}
+
+	resp, err := l.Auth.Login(ctx, &LoginRequestDto{
+		Username: l.Credentials.Username,
+		Password: l.Credentials.Password,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("api: refresh token via login: %w", err)
+	}
+	login, ok := resp.(*LoginResponseDto)
+	if !ok {
+		return "", time.Time{}, newUnexpectedResponseError("AuthControllerLogin", resp)
+	}
+
+	expiresAt, err := jwtExpiry(login.Response.AccessToken)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("api: decode login token expiry: %w", err)
+	}
+
+	l.token = login.Response.AccessToken
+	l.expiresAt = expiresAt
+	return l.token, l.expiresAt, nil
+}
+
+// jwtExpiry decodes the `exp` claim (seconds since the epoch) from an
+// unverified JWT's payload segment. The token's signature is not checked:
+// it was just issued by the panel we're talking to, so verification adds
+// nothing here.
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("malformed JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, err
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, fmt.Errorf("JWT has no exp claim")
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
+
+// ChainedTokenSource tries each of Sources in order, returning the first
+// one that succeeds. It's useful for e.g. preferring a FileTokenSource
+// mounted by the platform and falling back to a LoginTokenSource.
+type ChainedTokenSource struct {
+	Sources []TokenSource
+}
+
+// Token tries each source in order, returning the first successful result.
+// If every source fails, Token returns the last error encountered.
+func (c ChainedTokenSource) Token(ctx context.Context) (string, time.Time, error) {
+	var lastErr error
+	for _, source := range c.Sources {
+		token, expiresAt, err := source.Token(ctx)
+		if err == nil {
+			return token, expiresAt, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("api: no token sources configured")
+	}
+	return "", time.Time{}, lastErr
+}
+
+// WithReauthOn401 adds a Middleware that, on receiving a 401 response,
+// invalidates the cached token, fetches a fresh one from source, and
+// retries the request exactly once with the refreshed Authorization header
+// so a rotated credential doesn't require the caller to notice and re-auth
+// manually.
+func WithReauthOn401(source *CachedTokenSource) TransportOption {
+	return func(c *transportConfig) {
+		c.middlewares = append(c.middlewares, func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.RoundTrip(req)
+				if err != nil || resp.StatusCode != http.StatusUnauthorized {
+					return resp, err
+				}
+				resp.Body.Close()
+
+				source.Invalidate()
+				token, _, terr := source.Token(req.Context())
+				if terr != nil {
+					return nil, fmt.Errorf("api: reauth after 401: %w", terr)
+				}
+
+				retryReq := req.Clone(req.Context())
+				if req.Body != nil {
+					if req.GetBody == nil {
+						return nil, fmt.Errorf("api: cannot retry %s %s after reauth: request body is not replayable", req.Method, req.URL)
+					}
+					body, berr := req.GetBody()
+					if berr != nil {
+						return nil, fmt.Errorf("api: rewind request body for reauth retry: %w", berr)
+					}
+					retryReq.Body = body
+				}
+				retryReq.Header.Set("Authorization", "Bearer "+token)
+				return next.RoundTrip(retryReq)
+			})
+		})
+	}
+}
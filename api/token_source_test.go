@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingTokenSource struct {
+	calls   int32
+	delay   time.Duration
+	expires time.Duration
+}
+
+func (s *countingTokenSource) Token(ctx context.Context) (string, time.Time, error) {
+	n := atomic.AddInt32(&s.calls, 1)
+	if s.delay > 0 {
+		time.Sleep(s.delay)
+	}
+	return fmt.Sprintf("token-%d", n), time.Now().Add(s.expires), nil
+}
+
+// TestCachedTokenSourceSingleFlight verifies that concurrent callers racing
+// an expired token join a single in-flight refresh rather than each issuing
+// one against the wrapped TokenSource.
+func TestCachedTokenSourceSingleFlight(t *testing.T) {
+	source := &countingTokenSource{delay: 20 * time.Millisecond, expires: time.Minute}
+	cached := &CachedTokenSource{Source: source}
+
+	const callers = 10
+	tokens := make([]string, callers)
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			token, _, err := cached.Token(context.Background())
+			if err != nil {
+				t.Errorf("Token: %v", err)
+				return
+			}
+			tokens[i] = token
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&source.calls); got != 1 {
+		t.Fatalf("source.Token called %d times, want 1", got)
+	}
+	for i, token := range tokens {
+		if token != tokens[0] {
+			t.Errorf("caller %d got token %q, want %q", i, token, tokens[0])
+		}
+	}
+}
+
+// TestCachedTokenSourceInvalidateForcesRefresh verifies that Invalidate
+// causes the next Token call to bypass the cache and refresh.
+func TestCachedTokenSourceInvalidateForcesRefresh(t *testing.T) {
+	source := &countingTokenSource{expires: time.Minute}
+	cached := &CachedTokenSource{Source: source}
+
+	first, _, err := cached.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	cached.Invalidate()
+
+	second, _, err := cached.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if first == second {
+		t.Fatalf("expected a fresh token after Invalidate, got %q both times", first)
+	}
+}